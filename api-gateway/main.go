@@ -11,8 +11,14 @@ import (
 
 	"github.com/gin-gonic/gin"
 	pb "github.com/jtornovsky/notification-system/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -20,8 +26,71 @@ var (
 	redisClient *redis.Client
 	kafkaWriter *kafka.Writer
 	ctx         = context.Background()
+	tracer      = otel.Tracer("api-gateway")
 )
 
+// notificationsSentTotal mirrors delivery-service's metric of the same name so a single Grafana
+// dashboard can chart both sides of the Kafka hop; api-gateway only ever reports status=PENDING.
+var notificationsSentTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "notifications_sent_total",
+	Help: "Total notifications processed, by type and status.",
+}, []string{"type", "status"})
+
+// kafkaHeaderCarrier adapts kafka-go message headers to otel's propagation.TextMapCarrier so a
+// trace started here continues across the Kafka hop into delivery-service.
+type kafkaHeaderCarrier struct {
+	headers *[]kafka.Header
+}
+
+func (c kafkaHeaderCarrier) Get(key string) string {
+	for _, h := range *c.headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func (c kafkaHeaderCarrier) Set(key, value string) {
+	for i, h := range *c.headers {
+		if h.Key == key {
+			(*c.headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*c.headers = append(*c.headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (c kafkaHeaderCarrier) Keys() []string {
+	keys := make([]string, len(*c.headers))
+	for i, h := range *c.headers {
+		keys[i] = h.Key
+	}
+	return keys
+}
+
+// initTracing installs an always-sampling TracerProvider and a W3C trace-context propagator.
+// Exporting spans to a collector is a deployment concern (wire an SDK exporter before production).
+func initTracing() {
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	tracer = tp.Tracer("api-gateway")
+}
+
+// serveMetrics exposes /metrics on its own admin port, separate from the public API traffic.
+func serveMetrics(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	go func() {
+		log.Printf("📊 Metrics server listening on %s/metrics", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Printf("⚠️ Metrics server stopped: %v", err)
+		}
+	}()
+}
+
 type NotificationRequest struct {
 	UserID    string `json:"user_id"`
 	Type      string `json:"type"`
@@ -41,6 +110,9 @@ type NotificationResponse struct {
 }
 
 func main() {
+	initTracing()
+	serveMetrics(":9091")
+
 	redisClient = redis.NewClient(&redis.Options{
 		Addr: "localhost:6379",
 	})
@@ -82,6 +154,9 @@ func main() {
 }
 
 func createNotification(c *gin.Context) {
+	reqCtx, span := tracer.Start(c.Request.Context(), "createNotification")
+	defer span.End()
+
 	var req NotificationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -129,10 +204,15 @@ func createNotification(c *gin.Context) {
 	log.Printf("📦 Marshaled to Protobuf: %d bytes (vs JSON: %d bytes)",
 		len(pbData), len(notificationJSON))
 
+	// Propagate the trace into delivery-service via Kafka headers
+	var headers []kafka.Header
+	otel.GetTextMapPropagator().Inject(reqCtx, kafkaHeaderCarrier{headers: &headers})
+
 	// Send Protobuf bytes to Kafka
-	err = kafkaWriter.WriteMessages(ctx, kafka.Message{
-		Key:   []byte(notification.ID),
-		Value: pbData, // ← Protobuf bytes instead of JSON
+	err = kafkaWriter.WriteMessages(reqCtx, kafka.Message{
+		Key:     []byte(notification.ID),
+		Value:   pbData, // ← Protobuf bytes instead of JSON
+		Headers: headers,
 	})
 
 	if err != nil {
@@ -143,6 +223,8 @@ func createNotification(c *gin.Context) {
 
 	log.Printf("✅ Sent to Kafka topic 'notifications': ID=%s", notification.ID)
 
+	notificationsSentTotal.WithLabelValues(notification.Type, "PENDING").Inc()
+
 	c.JSON(http.StatusCreated, notification)
 }
 
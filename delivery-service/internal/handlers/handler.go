@@ -2,28 +2,42 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"log"
 	"time"
 
 	"delivery-service/internal/models"
 	"delivery-service/internal/mongo"
+	"delivery-service/internal/telemetry"
 
 	pb "github.com/jtornovsky/notification-system/proto"
 	"github.com/segmentio/kafka-go"
 	"google.golang.org/protobuf/proto"
 )
 
+// deliveryErrorHeaderKey carries the structured DeliveryError as JSON alongside a published
+// delivery event. It rides a Kafka header rather than a proto DeliveryEvent field because this
+// series doesn't own the proto schema and can't land a field there; the legacy ErrorMessage string
+// keeps the wire format unchanged for older consumers.
+const deliveryErrorHeaderKey = "x-delivery-error"
+
 // DeliverySimulator is a function that simulates delivery for a specific type
-type DeliverySimulator func(models.Notification) (string, int64, error)
+type DeliverySimulator func(ctx context.Context, notification models.Notification) (string, int64, error)
 
 // Handler is a generic notification delivery handler
 type Handler struct {
-	name         string
-	consumer     *kafka.Reader
-	producer     *kafka.Writer
-	mongoClient  *mongo.Client
-	simulator    DeliverySimulator
-	shutdownChan chan struct{}
+	name             string
+	brokers          []string
+	topic            string
+	groupID          string
+	consumer         *kafka.Reader
+	producer         *kafka.Writer
+	feedbackProducer *kafka.Writer
+	outboundWriter   *kafka.Writer
+	mongoClient      *mongo.Client
+	simulator        DeliverySimulator
+	shutdownChan     chan struct{}
 }
 
 // NewHandler creates a new generic delivery handler
@@ -50,20 +64,61 @@ func NewHandler(
 		Balancer: &kafka.LeastBytes{},
 	}
 
+	feedbackProducer := &kafka.Writer{
+		Addr:     kafka.TCP(brokers...),
+		Topic:    "token-feedback",
+		Balancer: &kafka.LeastBytes{},
+	}
+
+	// No fixed Topic: used to forward retries and dead letters, each message sets its own Topic.
+	outboundWriter := &kafka.Writer{
+		Addr:     kafka.TCP(brokers...),
+		Balancer: &kafka.LeastBytes{},
+	}
+
 	log.Printf("✓ %s handler initialized for topic: %s\n", name, topic)
 
 	return &Handler{
-		name:         name,
-		consumer:     consumer,
-		producer:     producer,
-		mongoClient:  mongoClient,
-		simulator:    simulator,
-		shutdownChan: make(chan struct{}),
+		name:             name,
+		brokers:          brokers,
+		topic:            topic,
+		groupID:          groupID,
+		consumer:         consumer,
+		producer:         producer,
+		feedbackProducer: feedbackProducer,
+		outboundWriter:   outboundWriter,
+		mongoClient:      mongoClient,
+		simulator:        simulator,
+		shutdownChan:     make(chan struct{}),
 	}, nil
 }
 
+// PublishFeedback emits a token-feedback event so an upstream user-preferences service can stop
+// retrying a recipient the provider has permanently rejected.
+func (h *Handler) PublishFeedback(ctx context.Context, feedback models.TokenFeedback) error {
+	payload, err := json.Marshal(feedback)
+	if err != nil {
+		return fmt.Errorf("failed to marshal token feedback: %w", err)
+	}
+
+	if err := h.feedbackProducer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(feedback.Recipient),
+		Value: payload,
+	}); err != nil {
+		return fmt.Errorf("failed to publish token feedback: %w", err)
+	}
+
+	log.Printf("⚠️ [%s] Published token feedback for %s: %s\n", h.name, feedback.Recipient, feedback.Reason)
+	return nil
+}
+
 // processMessage handles a single notification message
 func (h *Handler) processMessage(ctx context.Context, message kafka.Message) error {
+	// Pick up the trace started by the API gateway (or a previous retry hop) from Kafka headers.
+	ctx = telemetry.ExtractKafkaHeaders(ctx, message.Headers)
+	ctx, span := telemetry.StartSpan(ctx, "Handler.processMessage")
+	defer span.End()
+
 	// Deserialize Protobuf message
 	pbNotification := &pb.Notification{}
 	if err := proto.Unmarshal(message.Value, pbNotification); err != nil {
@@ -73,21 +128,54 @@ func (h *Handler) processMessage(ctx context.Context, message kafka.Message) err
 
 	log.Printf("📦 [%s] Received Protobuf message: %d bytes\n", h.name, len(message.Value))
 
-	// Convert to internal model
+	// Convert to internal model. Attempt rides the attemptHeaderKey Kafka header rather than a
+	// proto field: see attemptFromHeaders.
 	notification := models.Notification{
 		ID:        pbNotification.Id,
 		Type:      pb.NotificationType_name[int32(pbNotification.Type)],
 		Recipient: pbNotification.Recipient,
 		Subject:   pbNotification.Subject,
 		Message:   pbNotification.Message,
+		Attempt:   attemptFromHeaders(message.Headers),
 	}
 
-	status, deliveryTimeMs, err := h.simulator(notification)
+	status, deliveryTimeMs, err := h.simulator(ctx, notification)
 
-	errorMessage := ""
+	var deliveryErr *models.DeliveryError
 	if err != nil {
-		errorMessage = err.Error()
-		log.Printf("❌ [%s] Delivery failed: %v\n", h.name, err)
+		deliveryErr = models.AsDeliveryError(err)
+		deliveryErr.Attempt = notification.Attempt
+		log.Printf("❌ [%s] Delivery failed (attempt %d): %s\n", h.name, notification.Attempt+1, deliveryErr.Message)
+
+		if !deliveryErr.Retriable {
+			now := time.Now()
+			feedback := models.TokenFeedback{
+				Recipient: notification.Recipient,
+				Provider:  h.name,
+				Reason:    deliveryErr.Message,
+				FirstSeen: now,
+				LastSeen:  now,
+			}
+
+			if err := h.mongoClient.SaveTokenFeedback(ctx, feedback); err != nil {
+				log.Printf("⚠️ [%s] Failed to save token feedback: %v\n", h.name, err)
+			}
+
+			if err := h.PublishFeedback(ctx, feedback); err != nil {
+				log.Printf("⚠️ [%s] Failed to publish token feedback: %v\n", h.name, err)
+			}
+		} else {
+			decision := decideRetry(notification.Attempt)
+			status = decision.status
+
+			if decision.deadLetter {
+				if err := h.deadLetter(ctx, pbNotification, notification.Attempt); err != nil {
+					log.Printf("❌ [%s] Failed to dead-letter %s: %v\n", h.name, notification.ID, err)
+				}
+			} else if err := h.scheduleRetry(ctx, pbNotification, decision.nextAttempt); err != nil {
+				log.Printf("❌ [%s] Failed to schedule retry for %s: %v\n", h.name, notification.ID, err)
+			}
+		}
 	}
 
 	deliveryResult := models.DeliveryResult{
@@ -97,9 +185,13 @@ func (h *Handler) processMessage(ctx context.Context, message kafka.Message) err
 		Status:         status,
 		Timestamp:      time.Now(),
 		DeliveryTimeMs: deliveryTimeMs,
-		ErrorMessage:   errorMessage,
+		Error:          deliveryErr,
+		Attempt:        notification.Attempt,
 	}
 
+	telemetry.NotificationsSentTotal.WithLabelValues(notification.Type, status).Inc()
+	telemetry.DeliverySeconds.WithLabelValues(notification.Type).Observe(float64(deliveryTimeMs) / 1000)
+
 	if err := h.mongoClient.SaveDeliveryResult(ctx, deliveryResult); err != nil {
 		log.Printf("❌ [%s] Failed to save to MongoDB: %v\n", h.name, err)
 		return err
@@ -107,14 +199,41 @@ func (h *Handler) processMessage(ctx context.Context, message kafka.Message) err
 
 	log.Printf("✓ [%s] Saved delivery result to MongoDB\n", h.name)
 
+	// A retry is already published to its own topic; nothing further to report until it resolves.
+	if status == "RETRY_SCHEDULED" {
+		return nil
+	}
+
+	// The structured error, if any, rides in the deliveryErrorHeaderKey header so older consumers
+	// of DeliveryEvent that only read ErrorMessage keep working while newer ones parse the header
+	// for code/hint/retriable.
+	errorMessage := ""
+	errorJSON := ""
+	if deliveryResult.Error != nil {
+		errorMessage = deliveryResult.Error.Message
+		if b, err := json.Marshal(deliveryResult.Error); err != nil {
+			log.Printf("⚠️ [%s] Failed to marshal delivery error: %v\n", h.name, err)
+		} else {
+			errorJSON = string(b)
+		}
+	}
+
+	// DEAD_LETTERED has no pb.NotificationStatus value of its own; NotificationStatus_value would
+	// silently map it to the zero value (PENDING). Publish it as the last real delivery status
+	// (FAILED) instead and let deadLetteredHeaderKey carry the distinction out-of-band.
+	pbStatus := deliveryResult.Status
+	if pbStatus == "DEAD_LETTERED" {
+		pbStatus = "FAILED"
+	}
+
 	// Create Protobuf delivery event
 	pbDeliveryEvent := &pb.DeliveryEvent{
 		NotificationId: deliveryResult.NotificationID,
 		Type:           pb.NotificationType(pb.NotificationType_value[deliveryResult.Type]),
 		Recipient:      deliveryResult.Recipient,
-		Status:         pb.NotificationStatus(pb.NotificationStatus_value[deliveryResult.Status]),
+		Status:         pb.NotificationStatus(pb.NotificationStatus_value[pbStatus]),
 		ProcessedAt:    deliveryResult.Timestamp.UnixMilli(),
-		ErrorMessage:   deliveryResult.ErrorMessage,
+		ErrorMessage:   errorMessage,
 		DeliveryTimeMs: int32(deliveryResult.DeliveryTimeMs),
 	}
 
@@ -127,9 +246,19 @@ func (h *Handler) processMessage(ctx context.Context, message kafka.Message) err
 
 	log.Printf("📦 [%s] Publishing Protobuf delivery event: %d bytes\n", h.name, len(eventBytes))
 
+	var headers []kafka.Header
+	if errorJSON != "" {
+		headers = append(headers, kafka.Header{Key: deliveryErrorHeaderKey, Value: []byte(errorJSON)})
+	}
+	if deliveryResult.Status == "DEAD_LETTERED" {
+		headers = append(headers, kafka.Header{Key: deadLetteredHeaderKey, Value: []byte("true")})
+	}
+	telemetry.InjectKafkaHeaders(ctx, &headers)
+
 	if err := h.producer.WriteMessages(ctx, kafka.Message{
-		Key:   []byte(notification.ID),
-		Value: eventBytes,
+		Key:     []byte(notification.ID),
+		Value:   eventBytes,
+		Headers: headers,
 	}); err != nil {
 		log.Printf("❌ [%s] Failed to publish delivery event: %v\n", h.name, err)
 		return err
@@ -141,6 +270,12 @@ func (h *Handler) processMessage(ctx context.Context, message kafka.Message) err
 
 // Start begins consuming messages
 func (h *Handler) Start(ctx context.Context) error {
+	for attempt := int32(1); attempt < MaxAttempts; attempt++ {
+		go h.runRetryDelayer(ctx, attempt)
+	}
+
+	go h.reportConsumerLag(ctx)
+
 	log.Printf("🚀 [%s] Handler started, waiting for messages...\n", h.name)
 
 	for {
@@ -169,6 +304,23 @@ func (h *Handler) Start(ctx context.Context) error {
 	}
 }
 
+// reportConsumerLag polls the consumer's own stats and republishes kafka_consumer_lag until ctx
+// is done, so the lag is visible without needing a separate Kafka exporter.
+func (h *Handler) reportConsumerLag(ctx context.Context) {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stats := h.consumer.Stats()
+			telemetry.KafkaConsumerLag.WithLabelValues(h.topic, h.groupID).Set(float64(stats.Lag))
+		}
+	}
+}
+
 // Close gracefully shuts down the handler
 func (h *Handler) Close() error {
 	close(h.shutdownChan)
@@ -181,6 +333,14 @@ func (h *Handler) Close() error {
 		log.Printf("⚠️ [%s] Error closing producer: %v\n", h.name, err)
 	}
 
+	if err := h.feedbackProducer.Close(); err != nil {
+		log.Printf("⚠️ [%s] Error closing feedback producer: %v\n", h.name, err)
+	}
+
+	if err := h.outboundWriter.Close(); err != nil {
+		log.Printf("⚠️ [%s] Error closing outbound writer: %v\n", h.name, err)
+	}
+
 	log.Printf("✓ [%s] Handler closed\n", h.name)
 	return nil
 }
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"delivery-service/internal/models"
+	"delivery-service/internal/notifier"
+	"delivery-service/internal/telemetry"
+
+	pb "github.com/jtornovsky/notification-system/proto"
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/proto"
+)
+
+// DeliveryHandler is the lifecycle every handler in this package exposes, so main can start and
+// stop the per-type delivery handlers and the notifier fan-out handler uniformly.
+type DeliveryHandler interface {
+	Start(ctx context.Context) error
+	Close() error
+}
+
+// NotifyHandler consumes every delivery-events message and fans it out to the configured
+// notifier destinations (SMTP, generic webhook, script, ...).
+type NotifyHandler struct {
+	consumer     *kafka.Reader
+	registry     *notifier.Registry
+	shutdownChan chan struct{}
+}
+
+// NewNotifyHandler creates a delivery-events consumer that fans each event out via registry.
+func NewNotifyHandler(brokers []string, groupID string, registry *notifier.Registry) *NotifyHandler {
+	consumer := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     brokers,
+		Topic:       "delivery-events",
+		GroupID:     groupID,
+		MinBytes:    10e3,
+		MaxBytes:    10e6,
+		StartOffset: kafka.LastOffset,
+	})
+
+	log.Println("✓ Notify handler initialized for topic: delivery-events")
+
+	return &NotifyHandler{
+		consumer:     consumer,
+		registry:     registry,
+		shutdownChan: make(chan struct{}),
+	}
+}
+
+func (h *NotifyHandler) processMessage(ctx context.Context, message kafka.Message) error {
+	ctx = telemetry.ExtractKafkaHeaders(ctx, message.Headers)
+	ctx, span := telemetry.StartSpan(ctx, "NotifyHandler.processMessage")
+	defer span.End()
+
+	pbEvent := &pb.DeliveryEvent{}
+	if err := proto.Unmarshal(message.Value, pbEvent); err != nil {
+		log.Printf("❌ [Notify] Failed to unmarshal protobuf: %v\n", err)
+		return err
+	}
+
+	var deliveryErr *models.DeliveryError
+	deadLettered := false
+	for _, header := range message.Headers {
+		switch header.Key {
+		case deliveryErrorHeaderKey:
+			deliveryErr = &models.DeliveryError{}
+			if err := json.Unmarshal(header.Value, deliveryErr); err != nil {
+				log.Printf("⚠️ [Notify] Failed to unmarshal delivery error: %v\n", err)
+				deliveryErr = nil
+			}
+		case deadLetteredHeaderKey:
+			deadLettered = true
+		}
+	}
+
+	// The proto Status field carries "FAILED" for a dead-lettered notification (the enum has no
+	// DEAD_LETTERED value); deadLetteredHeaderKey restores the distinction for notifier output.
+	status := pb.NotificationStatus_name[int32(pbEvent.Status)]
+	if deadLettered {
+		status = "DEAD_LETTERED"
+	}
+
+	result := models.DeliveryResult{
+		NotificationID: pbEvent.NotificationId,
+		Type:           pb.NotificationType_name[int32(pbEvent.Type)],
+		Recipient:      pbEvent.Recipient,
+		Status:         status,
+		Timestamp:      time.UnixMilli(pbEvent.ProcessedAt),
+		DeliveryTimeMs: int64(pbEvent.DeliveryTimeMs),
+		Error:          deliveryErr,
+	}
+
+	if err := h.registry.Send(ctx, result); err != nil {
+		log.Printf("⚠️ [Notify] At least one destination failed for %s: %v\n", result.NotificationID, err)
+	}
+
+	return nil
+}
+
+// Start begins consuming delivery events and fanning them out.
+func (h *NotifyHandler) Start(ctx context.Context) error {
+	log.Println("🚀 [Notify] Handler started, waiting for messages...")
+
+	for {
+		select {
+		case <-h.shutdownChan:
+			log.Println("📭 [Notify] Handler shutting down...")
+			return nil
+		default:
+			message, err := h.consumer.FetchMessage(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("⚠️ [Notify] Error fetching message: %v\n", err)
+				}
+				continue
+			}
+
+			if err := h.processMessage(ctx, message); err != nil {
+				log.Printf("❌ [Notify] Error processing message: %v\n", err)
+			}
+
+			if err := h.consumer.CommitMessages(ctx, message); err != nil {
+				log.Printf("⚠️ [Notify] Failed to commit message: %v\n", err)
+			}
+		}
+	}
+}
+
+// Close gracefully shuts down the handler.
+func (h *NotifyHandler) Close() error {
+	close(h.shutdownChan)
+
+	if err := h.consumer.Close(); err != nil {
+		log.Printf("⚠️ [Notify] Error closing consumer: %v\n", err)
+	}
+
+	log.Println("✓ [Notify] Handler closed")
+	return nil
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"delivery-service/internal/telemetry"
+
+	pb "github.com/jtornovsky/notification-system/proto"
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/proto"
+)
+
+// retryHeaderKey carries the timestamp (RFC3339Nano) a delayer goroutine must wait for before
+// forwarding a retried message back onto the original topic.
+const retryHeaderKey = "x-retry-at"
+
+// attemptHeaderKey carries the attempt count (decimal, 0 for the first delivery try) alongside a
+// retried notification. Attempt rides a Kafka header rather than the proto Notification message
+// because this series doesn't own the proto schema and can't land a field there.
+const attemptHeaderKey = "x-attempt"
+
+// deadLetterTopic receives notifications that exhausted every retry attempt.
+const deadLetterTopic = "dead-letter-notifications"
+
+// deadLetteredHeaderKey marks a published DeliveryEvent whose notification was dead-lettered.
+// It rides a Kafka header rather than pb.NotificationStatus because the proto enum has no
+// DEAD_LETTERED value and this series can't land one there; the event's Status field stays at
+// the last real delivery status ("FAILED") so NotificationStatus_value never sees an unknown name.
+const deadLetteredHeaderKey = "x-dead-lettered"
+
+// retryBackoff is the delay before each retry attempt; its length bounds how many times a
+// notification is retried before being dead-lettered.
+var retryBackoff = []time.Duration{
+	1 * time.Second,
+	5 * time.Second,
+	30 * time.Second,
+	5 * time.Minute,
+	30 * time.Minute,
+}
+
+// maxAttemptsEnvVar lets an operator lower MaxAttempts without a code change. It can only lower
+// MaxAttempts from its default of len(retryBackoff)+1, never raise it, since there is no backoff
+// delay defined past the last retryBackoff entry.
+const maxAttemptsEnvVar = "DELIVERY_MAX_ATTEMPTS"
+
+// MaxAttempts is the total number of delivery attempts (the original plus every retry) before a
+// notification is dead-lettered. It defaults to len(retryBackoff)+1 and can be lowered via
+// DELIVERY_MAX_ATTEMPTS.
+var MaxAttempts = loadMaxAttempts()
+
+// loadMaxAttempts reads DELIVERY_MAX_ATTEMPTS, falling back to the default of len(retryBackoff)+1
+// when it is unset or out of range.
+func loadMaxAttempts() int32 {
+	defaultMaxAttempts := int32(len(retryBackoff) + 1)
+
+	raw := os.Getenv(maxAttemptsEnvVar)
+	if raw == "" {
+		return defaultMaxAttempts
+	}
+
+	parsed, err := strconv.Atoi(raw)
+	if err != nil || parsed < 1 || int32(parsed) > defaultMaxAttempts {
+		log.Printf("⚠️ Ignoring invalid %s=%q, using default %d\n", maxAttemptsEnvVar, raw, defaultMaxAttempts)
+		return defaultMaxAttempts
+	}
+
+	return int32(parsed)
+}
+
+// retryDecision is what processMessage should do next for a retriable failure.
+type retryDecision struct {
+	status      string
+	nextAttempt int32
+	deadLetter  bool
+}
+
+// decideRetry picks the next retry attempt or, once MaxAttempts is exhausted, dead-letters the
+// notification. attempt is the number of attempts already made (0 for the first delivery try).
+func decideRetry(attempt int32) retryDecision {
+	nextAttempt := attempt + 1
+	if nextAttempt < MaxAttempts {
+		return retryDecision{status: "RETRY_SCHEDULED", nextAttempt: nextAttempt}
+	}
+	return retryDecision{status: "DEAD_LETTERED", deadLetter: true}
+}
+
+// retryTopic names the per-attempt retry topic for a handler's original topic.
+func retryTopic(topic string, attempt int32) string {
+	return fmt.Sprintf("%s-retry-%d", topic, attempt)
+}
+
+// attemptFromHeaders reads the attempt count a previous hop stamped via attemptHeaderKey,
+// defaulting to 0 (the first delivery try) for a notification arriving fresh from the gateway.
+func attemptFromHeaders(headers []kafka.Header) int32 {
+	for _, header := range headers {
+		if header.Key == attemptHeaderKey {
+			if parsed, err := strconv.ParseInt(string(header.Value), 10, 32); err == nil {
+				return int32(parsed)
+			}
+		}
+	}
+	return 0
+}
+
+// scheduleRetry republishes a notification to its per-attempt retry topic, stamped with the time
+// a delayer goroutine should release it back onto the original topic.
+func (h *Handler) scheduleRetry(ctx context.Context, pbNotification *pb.Notification, attempt int32) error {
+	delay := retryBackoff[attempt-1]
+	retryAt := time.Now().Add(delay)
+
+	payload, err := proto.Marshal(pbNotification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal retry notification: %w", err)
+	}
+
+	headers := []kafka.Header{
+		{Key: retryHeaderKey, Value: []byte(retryAt.Format(time.RFC3339Nano))},
+		{Key: attemptHeaderKey, Value: []byte(strconv.Itoa(int(attempt)))},
+	}
+	telemetry.InjectKafkaHeaders(ctx, &headers)
+
+	msg := kafka.Message{
+		Topic:   retryTopic(h.topic, attempt),
+		Key:     []byte(pbNotification.Id),
+		Value:   payload,
+		Headers: headers,
+	}
+
+	if err := h.outboundWriter.WriteMessages(ctx, msg); err != nil {
+		return fmt.Errorf("failed to publish to retry topic: %w", err)
+	}
+
+	log.Printf("🔁 [%s] Scheduled retry %d/%d for %s at %s\n", h.name, attempt, MaxAttempts-1, pbNotification.Id, retryAt.Format(time.RFC3339))
+	return nil
+}
+
+// deadLetter publishes a notification that exhausted MaxAttempts to the dead-letter topic.
+func (h *Handler) deadLetter(ctx context.Context, pbNotification *pb.Notification, attempt int32) error {
+	payload, err := proto.Marshal(pbNotification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead-lettered notification: %w", err)
+	}
+
+	var headers []kafka.Header
+	telemetry.InjectKafkaHeaders(ctx, &headers)
+
+	msg := kafka.Message{
+		Topic:   deadLetterTopic,
+		Key:     []byte(pbNotification.Id),
+		Value:   payload,
+		Headers: headers,
+	}
+
+	if err := h.outboundWriter.WriteMessages(ctx, msg); err != nil {
+		return fmt.Errorf("failed to publish to dead-letter topic: %w", err)
+	}
+
+	log.Printf("☠️ [%s] Dead-lettered %s after %d attempts\n", h.name, pbNotification.Id, attempt+1)
+	return nil
+}
+
+// runRetryDelayer consumes a single retry-<n> topic and forwards each message back onto the
+// original topic once its x-retry-at header has elapsed, so it is picked up by Start like any
+// other incoming notification.
+func (h *Handler) runRetryDelayer(ctx context.Context, attempt int32) {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     h.brokers,
+		Topic:       retryTopic(h.topic, attempt),
+		GroupID:     fmt.Sprintf("%s-retry-%d", h.groupID, attempt),
+		MinBytes:    10e3,
+		MaxBytes:    10e6,
+		StartOffset: kafka.LastOffset,
+	})
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Printf("⚠️ [%s] Error closing retry-%d reader: %v\n", h.name, attempt, err)
+		}
+	}()
+
+	for {
+		message, err := reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("⚠️ [%s] Error fetching retry-%d message: %v\n", h.name, attempt, err)
+			continue
+		}
+
+		retryAt := time.Now()
+		for _, header := range message.Headers {
+			if header.Key == retryHeaderKey {
+				if parsed, err := time.Parse(time.RFC3339Nano, string(header.Value)); err == nil {
+					retryAt = parsed
+				}
+			}
+		}
+
+		if wait := time.Until(retryAt); wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		forwardHeaders := make([]kafka.Header, 0, len(message.Headers))
+		for _, header := range message.Headers {
+			if header.Key != retryHeaderKey {
+				forwardHeaders = append(forwardHeaders, header)
+			}
+		}
+
+		if err := h.outboundWriter.WriteMessages(ctx, kafka.Message{
+			Topic:   h.topic,
+			Key:     message.Key,
+			Value:   message.Value,
+			Headers: forwardHeaders,
+		}); err != nil {
+			log.Printf("⚠️ [%s] Failed to forward retry-%d message: %v\n", h.name, attempt, err)
+			continue
+		}
+
+		if err := reader.CommitMessages(ctx, message); err != nil {
+			log.Printf("⚠️ [%s] Failed to commit retry-%d message: %v\n", h.name, attempt, err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import "testing"
+
+// TestDecideRetry_EventuallyDeadLetters simulates a 100% failure rate: every attempt up to
+// MaxAttempts-1 should schedule the next retry, and the final attempt should dead-letter.
+func TestDecideRetry_EventuallyDeadLetters(t *testing.T) {
+	for attempt := int32(0); attempt < MaxAttempts-1; attempt++ {
+		decision := decideRetry(attempt)
+		if decision.deadLetter {
+			t.Fatalf("attempt %d: expected a retry, got dead-letter", attempt)
+		}
+		if decision.status != "RETRY_SCHEDULED" {
+			t.Fatalf("attempt %d: expected status RETRY_SCHEDULED, got %q", attempt, decision.status)
+		}
+		if decision.nextAttempt != attempt+1 {
+			t.Fatalf("attempt %d: expected nextAttempt %d, got %d", attempt, attempt+1, decision.nextAttempt)
+		}
+	}
+
+	final := decideRetry(MaxAttempts - 1)
+	if !final.deadLetter {
+		t.Fatalf("expected dead-letter after %d attempts, got a retry", MaxAttempts)
+	}
+	if final.status != "DEAD_LETTERED" {
+		t.Fatalf("expected status DEAD_LETTERED, got %q", final.status)
+	}
+}
+
+func TestLoadMaxAttempts(t *testing.T) {
+	defaultMaxAttempts := int32(len(retryBackoff) + 1)
+
+	t.Run("unset falls back to the backoff table length plus one", func(t *testing.T) {
+		t.Setenv(maxAttemptsEnvVar, "")
+		if got := loadMaxAttempts(); got != defaultMaxAttempts {
+			t.Fatalf("loadMaxAttempts() = %d, want %d", got, defaultMaxAttempts)
+		}
+	})
+
+	t.Run("valid override is honored", func(t *testing.T) {
+		t.Setenv(maxAttemptsEnvVar, "2")
+		if got := loadMaxAttempts(); got != 2 {
+			t.Fatalf("loadMaxAttempts() = %d, want 2", got)
+		}
+	})
+
+	t.Run("invalid or out-of-range values fall back to the default", func(t *testing.T) {
+		for _, raw := range []string{"not-a-number", "0", "-1", "999"} {
+			t.Setenv(maxAttemptsEnvVar, raw)
+			if got := loadMaxAttempts(); got != defaultMaxAttempts {
+				t.Fatalf("loadMaxAttempts() with %s=%q = %d, want %d", maxAttemptsEnvVar, raw, got, defaultMaxAttempts)
+			}
+		}
+	})
+}
+
+func TestRetryTopic(t *testing.T) {
+	got := retryTopic("email-notifications", 2)
+	want := "email-notifications-retry-2"
+	if got != want {
+		t.Fatalf("retryTopic() = %q, want %q", got, want)
+	}
+}
@@ -1,55 +1,74 @@
 package handlers
 
 import (
-	"fmt"
+	"context"
 	"log"
 	"math/rand"
 	"time"
 
 	"delivery-service/internal/models"
+	"delivery-service/internal/telemetry"
 )
 
 // DeliveryConfig holds configuration for simulating a delivery type
 type DeliveryConfig struct {
-	Name         string  // "Email", "SMS", "Push"
-	MinDelayMs   int     // Minimum delivery time
-	MaxDelayMs   int     // Maximum delivery time
-	FailureRate  float32 // 0.10 = 10% failure rate
-	ErrorMessage string  // Error message when fails
-	SuccessEmoji string  // Emoji for success logs
+	Name                      string           // "Email", "SMS", "Push"
+	MinDelayMs                int              // Minimum delivery time
+	MaxDelayMs                int              // Maximum delivery time
+	FailureRate               float32          // 0.10 = 10% transient failure rate
+	ErrorCode                 models.ErrorCode // Code for a transient failure
+	ErrorMessage              string           // Error message for a transient failure
+	ErrorHint                 string           // Remediation hint for a transient failure
+	ProviderResponse          string           // Simulated raw provider response for a transient failure
+	PermanentFailureRate      float32          // 0.02 = 2% permanent addressing failure rate (hard bounce, dead number, ...)
+	PermanentErrorCode        models.ErrorCode // Code for a permanent addressing failure
+	PermanentErrorMessage     string           // Error message for a permanent addressing failure
+	PermanentErrorHint        string           // Remediation hint for a permanent addressing failure
+	PermanentProviderResponse string           // Simulated raw provider response for a permanent failure
+	SuccessEmoji              string           // Emoji for success logs
 }
 
 var (
 	emailConfig = DeliveryConfig{
-		Name:         "Email",
-		MinDelayMs:   50,
-		MaxDelayMs:   200,
-		FailureRate:  0.10,
-		ErrorMessage: "SMTP connection timeout",
-		SuccessEmoji: "📧",
+		Name:                      "Email",
+		MinDelayMs:                50,
+		MaxDelayMs:                200,
+		FailureRate:               0.10,
+		ErrorCode:                 models.ErrorCodeSMTPTimeout,
+		ErrorMessage:              "SMTP connection timeout",
+		ErrorHint:                 "Verify the SMTP host is reachable and not rate-limiting this sender",
+		ProviderResponse:          "421 4.4.2 connection timed out",
+		PermanentFailureRate:      0.02,
+		PermanentErrorCode:        models.ErrorCodeInvalidToken,
+		PermanentErrorMessage:     "SMTP hard bounce: mailbox does not exist (550)",
+		PermanentErrorHint:        "Verify SPF/DKIM DNS records and that the mailbox still exists",
+		PermanentProviderResponse: "550 5.1.1 mailbox does not exist",
+		SuccessEmoji:              "📧",
 	}
 
 	smsConfig = DeliveryConfig{
-		Name:         "SMS",
-		MinDelayMs:   30,
-		MaxDelayMs:   100,
-		FailureRate:  0.10,
-		ErrorMessage: "carrier gateway unreachable",
-		SuccessEmoji: "📱",
-	}
-
-	pushConfig = DeliveryConfig{
-		Name:         "Push",
-		MinDelayMs:   20,
-		MaxDelayMs:   80,
-		FailureRate:  0.10,
-		ErrorMessage: "device token invalid",
-		SuccessEmoji: "🔔",
+		Name:                      "SMS",
+		MinDelayMs:                30,
+		MaxDelayMs:                100,
+		FailureRate:               0.10,
+		ErrorCode:                 models.ErrorCodeCarrierUnreachable,
+		ErrorMessage:              "carrier gateway unreachable",
+		ErrorHint:                 "Check the SMS carrier's status page and gateway credentials",
+		ProviderResponse:          "503 Service Unavailable",
+		PermanentFailureRate:      0.02,
+		PermanentErrorCode:        models.ErrorCodeInvalidToken,
+		PermanentErrorMessage:     "carrier rejected: invalid number",
+		PermanentErrorHint:        "Confirm the recipient number is still in service",
+		PermanentProviderResponse: "400 invalid destination address",
+		SuccessEmoji:              "📱",
 	}
 )
 
 // simulateDelivery is the generic delivery simulation function
-func simulateDelivery(notification models.Notification, config DeliveryConfig) (string, int64, error) {
+func simulateDelivery(ctx context.Context, notification models.Notification, config DeliveryConfig) (string, int64, error) {
+	_, span := telemetry.StartSpan(ctx, "simulateDelivery."+config.Name)
+	defer span.End()
+
 	startTime := time.Now()
 
 	// Simulate network delay
@@ -59,9 +78,25 @@ func simulateDelivery(notification models.Notification, config DeliveryConfig) (
 
 	deliveryTime := time.Since(startTime).Milliseconds()
 
-	// Random failure
-	if rand.Float32() < config.FailureRate {
-		return "FAILED", deliveryTime, fmt.Errorf(config.ErrorMessage)
+	// Random failure: permanent addressing failures are rolled first since they're rarer
+	roll := rand.Float32()
+	switch {
+	case roll < config.PermanentFailureRate:
+		return "FAILED", deliveryTime, &models.DeliveryError{
+			Code:             config.PermanentErrorCode,
+			Message:          config.PermanentErrorMessage,
+			Hint:             config.PermanentErrorHint,
+			ProviderResponse: config.PermanentProviderResponse,
+			Retriable:        false,
+		}
+	case roll < config.PermanentFailureRate+config.FailureRate:
+		return "FAILED", deliveryTime, &models.DeliveryError{
+			Code:             config.ErrorCode,
+			Message:          config.ErrorMessage,
+			Hint:             config.ErrorHint,
+			ProviderResponse: config.ProviderResponse,
+			Retriable:        true,
+		}
 	}
 
 	// Success
@@ -70,16 +105,11 @@ func simulateDelivery(notification models.Notification, config DeliveryConfig) (
 }
 
 // SimulateEmailDelivery simulates sending an email with random failures
-func SimulateEmailDelivery(notification models.Notification) (string, int64, error) {
-	return simulateDelivery(notification, emailConfig)
+func SimulateEmailDelivery(ctx context.Context, notification models.Notification) (string, int64, error) {
+	return simulateDelivery(ctx, notification, emailConfig)
 }
 
 // SimulateSmsDelivery simulates sending an SMS with random failures
-func SimulateSmsDelivery(notification models.Notification) (string, int64, error) {
-	return simulateDelivery(notification, smsConfig)
-}
-
-// SimulatePushDelivery simulates sending a push notification with random failures
-func SimulatePushDelivery(notification models.Notification) (string, int64, error) {
-	return simulateDelivery(notification, pushConfig)
+func SimulateSmsDelivery(ctx context.Context, notification models.Notification) (string, int64, error) {
+	return simulateDelivery(ctx, notification, smsConfig)
 }
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrorCode is a stable, machine-readable classification for a delivery failure, so dashboards
+// and alerts can key off Code instead of parsing Message.
+type ErrorCode string
+
+const (
+	ErrorCodeSMTPTimeout        ErrorCode = "SMTP_TIMEOUT"
+	ErrorCodeCarrierUnreachable ErrorCode = "CARRIER_UNREACHABLE"
+	ErrorCodeInvalidToken       ErrorCode = "INVALID_TOKEN"
+	ErrorCodeRateLimited        ErrorCode = "RATE_LIMITED"
+	ErrorCodeUnknown            ErrorCode = "UNKNOWN"
+)
+
+// DeliveryError is the structured failure reason attached to a DeliveryResult. It implements
+// error so simulators and providers can return it (or wrap it further with WithHint) exactly like
+// any other error.
+type DeliveryError struct {
+	Code             ErrorCode `json:"code" bson:"code"`
+	Message          string    `json:"message" bson:"message"`
+	Hint             string    `json:"hint,omitempty" bson:"hint,omitempty"`
+	ProviderResponse string    `json:"provider_response,omitempty" bson:"provider_response,omitempty"`
+	Retriable        bool      `json:"retriable" bson:"retriable"`
+	Attempt          int32     `json:"attempt" bson:"attempt"`
+}
+
+func (e *DeliveryError) Error() string {
+	return e.Message
+}
+
+// classifyMarkers maps a substring found in a plain error's message to the ErrorCode, retriability
+// and remediation hint AsDeliveryError assigns it. Order matters: the first match wins. This is
+// the fallback path for providers that haven't been updated to construct a *DeliveryError
+// themselves yet.
+var classifyMarkers = []struct {
+	marker    string
+	code      ErrorCode
+	retriable bool
+	hint      string
+}{
+	{"SMTP connection timeout", ErrorCodeSMTPTimeout, true, "Verify the SMTP host is reachable and not rate-limiting this sender"},
+	{"hard bounce", ErrorCodeInvalidToken, false, "Verify SPF/DKIM DNS records and that the mailbox still exists"},
+	{"carrier gateway unreachable", ErrorCodeCarrierUnreachable, true, "Check the SMS carrier's status page and gateway credentials"},
+	{"invalid number", ErrorCodeInvalidToken, false, "Confirm the recipient number is still in service"},
+	{"rejected device token", ErrorCodeInvalidToken, false, "Recipient uninstalled the app or revoked push permission; drop the token"},
+	{"rate limit", ErrorCodeRateLimited, true, "Back off and retry; consider raising the provider's rate limit"},
+}
+
+// AsDeliveryError converts err into a *DeliveryError, classifying it by known message markers. If
+// err already is (or wraps) a *DeliveryError, that value is returned unchanged instead of being
+// reclassified.
+func AsDeliveryError(err error) *DeliveryError {
+	if err == nil {
+		return nil
+	}
+
+	var de *DeliveryError
+	if errors.As(err, &de) {
+		return de
+	}
+
+	msg := err.Error()
+	for _, m := range classifyMarkers {
+		if strings.Contains(msg, m.marker) {
+			return &DeliveryError{Code: m.code, Message: msg, Hint: m.hint, Retriable: m.retriable}
+		}
+	}
+
+	return &DeliveryError{Code: ErrorCodeUnknown, Message: msg, Retriable: true}
+}
+
+// WithHint attaches (or overwrites) a human-readable remediation hint on err, converting it to a
+// *DeliveryError via AsDeliveryError first if it isn't one already. This lets a provider attach a
+// hint without the persistence layer needing to know how to classify that provider's errors.
+func WithHint(err error, hint string) error {
+	de := AsDeliveryError(err)
+	if de == nil {
+		return nil
+	}
+	de.Hint = hint
+	return de
+}
@@ -9,16 +9,30 @@ type Notification struct {
 	Recipient string `json:"recipient"`
 	Message   string `json:"message"`
 	Subject   string `json:"subject,omitempty"`
+	Attempt   int32  `json:"attempt,omitempty"`
 }
 
 // DeliveryResult represents a notification delivery attempt
 // Used for both MongoDB storage (bson tags) and Kafka events (json tags)
 type DeliveryResult struct {
-	NotificationID string    `json:"notification_id" bson:"notification_id"`
-	Type           string    `json:"type" bson:"type"`
-	Recipient      string    `json:"recipient" bson:"recipient"`
-	Status         string    `json:"status" bson:"status"`
-	Timestamp      time.Time `json:"timestamp" bson:"timestamp"`
-	DeliveryTimeMs int64     `json:"delivery_time_ms" bson:"delivery_time_ms"`
-	ErrorMessage   string    `json:"error_message,omitempty" bson:"error_message,omitempty"`
+	NotificationID string         `json:"notification_id" bson:"notification_id"`
+	Type           string         `json:"type" bson:"type"`
+	Recipient      string         `json:"recipient" bson:"recipient"`
+	Status         string         `json:"status" bson:"status"`
+	Timestamp      time.Time      `json:"timestamp" bson:"timestamp"`
+	DeliveryTimeMs int64          `json:"delivery_time_ms" bson:"delivery_time_ms"`
+	Error          *DeliveryError `json:"error,omitempty" bson:"error,omitempty"`
+	Attempt        int32          `json:"attempt" bson:"attempt"`
+}
+
+// TokenFeedback is a tombstone for a recipient a provider has permanently rejected (dead device
+// token, hard-bounced address, disconnected number). Persisted in the invalid_tokens collection
+// and published to the token-feedback topic so an upstream user-preferences service can unsubscribe
+// the recipient instead of retrying it forever.
+type TokenFeedback struct {
+	Recipient string    `json:"recipient" bson:"recipient"`
+	Provider  string    `json:"provider" bson:"provider"`
+	Reason    string    `json:"reason" bson:"reason"`
+	FirstSeen time.Time `json:"first_seen" bson:"first_seen"`
+	LastSeen  time.Time `json:"last_seen" bson:"last_seen"`
 }
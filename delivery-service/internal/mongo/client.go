@@ -7,14 +7,17 @@ import (
 	"time"
 
 	"delivery-service/internal/models"
+	"delivery-service/internal/telemetry"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type Client struct {
-	client     *mongo.Client
-	collection *mongo.Collection
+	client        *mongo.Client
+	collection    *mongo.Collection
+	invalidTokens *mongo.Collection
 }
 
 // NewClient creates a new MongoDB connection
@@ -35,9 +38,12 @@ func NewClient(uri, database, collection string) (*Client, error) {
 
 	log.Println("✓ Connected to MongoDB")
 
+	db := client.Database(database)
+
 	return &Client{
-		client:     client,
-		collection: client.Database(database).Collection(collection),
+		client:        client,
+		collection:    db.Collection(collection),
+		invalidTokens: db.Collection("invalid_tokens"),
 	}, nil
 }
 
@@ -56,9 +62,36 @@ func (c *Client) Close() error {
 
 // SaveDeliveryResult inserts a delivery result into MongoDB
 func (c *Client) SaveDeliveryResult(ctx context.Context, result models.DeliveryResult) error {
+	ctx, span := telemetry.StartSpan(ctx, "mongo.SaveDeliveryResult")
+	defer span.End()
+
 	_, err := c.collection.InsertOne(ctx, result)
 	if err != nil {
+		telemetry.MongoWriteErrorsTotal.Inc()
 		return fmt.Errorf("failed to save delivery result: %w", err)
 	}
 	return nil
 }
+
+// SaveTokenFeedback upserts a tombstone for a permanently-rejected recipient, keyed by
+// recipient+provider so repeat failures extend LastSeen instead of growing the collection unbounded.
+func (c *Client) SaveTokenFeedback(ctx context.Context, feedback models.TokenFeedback) error {
+	filter := bson.M{"recipient": feedback.Recipient, "provider": feedback.Provider}
+	update := bson.M{
+		"$set": bson.M{
+			"reason":    feedback.Reason,
+			"last_seen": feedback.LastSeen,
+		},
+		"$setOnInsert": bson.M{
+			"recipient":  feedback.Recipient,
+			"provider":   feedback.Provider,
+			"first_seen": feedback.FirstSeen,
+		},
+	}
+
+	if _, err := c.invalidTokens.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true)); err != nil {
+		telemetry.MongoWriteErrorsTotal.Inc()
+		return fmt.Errorf("failed to save token feedback: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// Package notifier fans delivery results out to operator-configured destination URLs
+// (smtp://, generic+https://, script:///, ...) without the delivery pipeline knowing which
+// sinks exist. New destinations are added by registering a scheme, not by changing callers.
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+
+	"delivery-service/internal/models"
+)
+
+// Notifier sends a single delivery result to one configured destination.
+type Notifier interface {
+	Send(ctx context.Context, result models.DeliveryResult) error
+}
+
+// factory builds a Notifier from a parsed destination URL.
+type factory func(u *url.URL) (Notifier, error)
+
+// factories maps a URL scheme to the Notifier it builds. Built-in schemes are registered in
+// init(); new destination kinds are added the same way without touching Registry itself.
+var factories = map[string]factory{}
+
+func registerFactory(scheme string, f factory) {
+	factories[scheme] = f
+}
+
+// Registry holds every destination passed to NewRegistry (or read from the NOTIFY_URLS environment
+// variable, one URL per line, by NewRegistryFromEnv) and fans a delivery result out to all of them.
+// No YAML config file is read.
+type Registry struct {
+	destinations []Notifier
+}
+
+// NewRegistry parses each destination URL and builds its Notifier.
+func NewRegistry(urls []string) (*Registry, error) {
+	registry := &Registry{}
+
+	for _, rawURL := range urls {
+		rawURL = strings.TrimSpace(rawURL)
+		if rawURL == "" {
+			continue
+		}
+
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse notifier URL %q: %w", rawURL, err)
+		}
+
+		build, ok := factories[u.Scheme]
+		if !ok {
+			return nil, fmt.Errorf("no notifier registered for scheme %q", u.Scheme)
+		}
+
+		destination, err := build(u)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build %s notifier: %w", u.Scheme, err)
+		}
+
+		registry.destinations = append(registry.destinations, destination)
+	}
+
+	log.Printf("✓ Notifier registry configured with %d destination(s)\n", len(registry.destinations))
+	return registry, nil
+}
+
+// NewRegistryFromEnv builds a Registry from the newline-separated NOTIFY_URLS environment
+// variable. Newline, not comma, separates destinations: a destination's own query string (e.g.
+// smtp://...?toAddresses=a@b.com,c@d.com) is free to use commas without being shredded by the
+// top-level split.
+func NewRegistryFromEnv() (*Registry, error) {
+	raw := os.Getenv("NOTIFY_URLS")
+	if raw == "" {
+		return &Registry{}, nil
+	}
+	return NewRegistry(strings.Split(raw, "\n"))
+}
+
+// Send fans the delivery result out to every configured destination. A failing destination is
+// logged and does not stop delivery to the others.
+func (r *Registry) Send(ctx context.Context, result models.DeliveryResult) error {
+	var firstErr error
+
+	for _, destination := range r.destinations {
+		if err := destination.Send(ctx, result); err != nil {
+			log.Printf("⚠️ Notifier destination failed: %v\n", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return firstErr
+}
@@ -0,0 +1,22 @@
+package notifier
+
+import "testing"
+
+// TestNewRegistry_CommaInDestinationQueryStringSurvives guards against the top-level NOTIFY_URLS
+// split shredding a destination URL whose own query string uses commas, like smtp://'s
+// toAddresses.
+func TestNewRegistry_CommaInDestinationQueryStringSurvives(t *testing.T) {
+	urls := []string{
+		"smtp://mail.example.com:587/?fromAddress=alerts@example.com&toAddresses=a@example.com,b@example.com",
+		"script:///usr/local/bin/notify.sh",
+	}
+
+	registry, err := NewRegistry(urls)
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	if len(registry.destinations) != len(urls) {
+		t.Fatalf("len(destinations) = %d, want %d", len(registry.destinations), len(urls))
+	}
+}
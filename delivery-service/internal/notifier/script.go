@@ -0,0 +1,45 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os/exec"
+
+	"delivery-service/internal/models"
+)
+
+func init() {
+	registerFactory("script", newScriptNotifier)
+}
+
+// scriptNotifier runs a local script:///path/on/disk executable, passing the delivery result as
+// JSON on stdin.
+type scriptNotifier struct {
+	path string
+}
+
+func newScriptNotifier(u *url.URL) (Notifier, error) {
+	if u.Path == "" {
+		return nil, fmt.Errorf("script notifier URL is missing a path")
+	}
+	return &scriptNotifier{path: u.Path}, nil
+}
+
+// Send runs the configured script with the delivery result JSON on stdin.
+func (n *scriptNotifier) Send(ctx context.Context, result models.DeliveryResult) error {
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal script notifier payload: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, n.path)
+	cmd.Stdin = bytes.NewReader(payload)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("notifier script failed: %w (output: %s)", err, output)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package notifier
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestNewSMTPNotifier_MultipleRecipients(t *testing.T) {
+	u, err := url.Parse("smtp://mail.example.com:587/?fromAddress=alerts@example.com&toAddresses=a@example.com,b@example.com")
+	if err != nil {
+		t.Fatalf("failed to parse test URL: %v", err)
+	}
+
+	n, err := newSMTPNotifier(u)
+	if err != nil {
+		t.Fatalf("newSMTPNotifier() error = %v", err)
+	}
+
+	smtpN, ok := n.(*smtpNotifier)
+	if !ok {
+		t.Fatalf("newSMTPNotifier() returned %T, want *smtpNotifier", n)
+	}
+
+	want := []string{"a@example.com", "b@example.com"}
+	if len(smtpN.toAddresses) != len(want) {
+		t.Fatalf("toAddresses = %v, want %v", smtpN.toAddresses, want)
+	}
+	for i, addr := range want {
+		if smtpN.toAddresses[i] != addr {
+			t.Fatalf("toAddresses[%d] = %q, want %q", i, smtpN.toAddresses[i], addr)
+		}
+	}
+}
+
+func TestNewSMTPNotifier_MissingToAddresses(t *testing.T) {
+	u, err := url.Parse("smtp://mail.example.com:587/?fromAddress=alerts@example.com")
+	if err != nil {
+		t.Fatalf("failed to parse test URL: %v", err)
+	}
+
+	if _, err := newSMTPNotifier(u); err == nil {
+		t.Fatal("newSMTPNotifier() expected an error for a missing toAddresses, got nil")
+	}
+}
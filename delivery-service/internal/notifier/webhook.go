@@ -0,0 +1,63 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"delivery-service/internal/models"
+)
+
+func init() {
+	registerFactory("generic+http", newWebhookNotifier)
+	registerFactory("generic+https", newWebhookNotifier)
+}
+
+// webhookNotifier POSTs a delivery result as JSON to an arbitrary generic+https://host/path
+// destination URL, stripping the generic+ prefix to recover the real scheme.
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func newWebhookNotifier(u *url.URL) (Notifier, error) {
+	scheme := strings.TrimPrefix(u.Scheme, "generic+")
+
+	target := *u
+	target.Scheme = scheme
+
+	return &webhookNotifier{
+		url:    target.String(),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Send POSTs result as JSON to the configured webhook.
+func (n *webhookNotifier) Send(ctx context.Context, result models.DeliveryResult) error {
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("content-type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"delivery-service/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const apnsHost = "https://api.push.apple.com"
+
+// APNsConfig configures the Apple Push Notification service provider.
+type APNsConfig struct {
+	KeyID           string // APNs Auth Key ID (kid)
+	TeamID          string // Apple Developer Team ID (iss)
+	BundleID        string // app bundle ID, sent as apns-topic
+	PrivateKeyPath  string // path to the .p8 signing key
+	MaxConnsPerHost int    // HTTP/2 connection pool size
+	RateLimitPerSec int    // requests/sec budget for this pool (Apple recommends ~9k/sec/connection)
+}
+
+// APNsProvider sends push notifications to Apple devices over HTTP/2 using JWT provider auth.
+type APNsProvider struct {
+	cfg        APNsConfig
+	client     *http.Client
+	limiter    *TokenBucket
+	signingKey *ecdsa.PrivateKey
+
+	mu          sync.Mutex
+	cachedToken string
+	tokenExpiry time.Time
+}
+
+// NewAPNsProvider loads the signing key and builds an HTTP/2 client pooled and rate-limited per cfg.
+// A cfg with no KeyID registers the provider without credentials; Send then fails retriably until
+// the deployment supplies real APNs config.
+func NewAPNsProvider(cfg APNsConfig) (*APNsProvider, error) {
+	limiter := NewTokenBucket(cfg.RateLimitPerSec)
+
+	if cfg.KeyID == "" {
+		return &APNsProvider{cfg: cfg, limiter: limiter}, nil
+	}
+
+	keyBytes, err := os.ReadFile(cfg.PrivateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read APNs private key: %w", err)
+	}
+
+	block, _ := pem.Decode(keyBytes)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode APNs private key PEM")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse APNs private key: %w", err)
+	}
+
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("APNs private key is not an ECDSA key")
+	}
+
+	transport := &http.Transport{
+		ForceAttemptHTTP2:   true,
+		MaxConnsPerHost:     cfg.MaxConnsPerHost,
+		MaxIdleConnsPerHost: cfg.MaxConnsPerHost,
+	}
+
+	return &APNsProvider{
+		cfg:        cfg,
+		client:     &http.Client{Transport: transport, Timeout: 10 * time.Second},
+		limiter:    limiter,
+		signingKey: ecKey,
+	}, nil
+}
+
+// Name identifies this provider in logs and metrics.
+func (p *APNsProvider) Name() string { return "apns" }
+
+// Send delivers a single push notification to APNs and classifies the response.
+func (p *APNsProvider) Send(ctx context.Context, notification models.Notification) (Result, error) {
+	if p.signingKey == nil {
+		return ResultFailedRetriable, fmt.Errorf("APNs provider not configured")
+	}
+
+	if err := p.limiter.Wait(ctx); err != nil {
+		return ResultFailedRetriable, fmt.Errorf("APNs rate limiter: %w", err)
+	}
+
+	token, err := p.providerToken()
+	if err != nil {
+		return ResultFailedRetriable, fmt.Errorf("failed to build APNs provider token: %w", err)
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{
+				"title": notification.Subject,
+				"body":  notification.Message,
+			},
+		},
+	})
+	if err != nil {
+		return ResultFailedRetriable, fmt.Errorf("failed to marshal APNs payload: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/3/device/%s", apnsHost, notification.Recipient)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return ResultFailedRetriable, fmt.Errorf("failed to build APNs request: %w", err)
+	}
+
+	req.Header.Set("authorization", "bearer "+token)
+	req.Header.Set("apns-topic", p.cfg.BundleID)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("content-type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return ResultFailedRetriable, fmt.Errorf("APNs request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode == http.StatusOK {
+		return ResultSent, nil
+	}
+
+	var apnsErr struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.Unmarshal(body, &apnsErr)
+
+	switch apnsErr.Reason {
+	case "BadDeviceToken", "Unregistered":
+		return ResultInvalidToken, NewProviderError(fmt.Sprintf("APNs rejected device token: %s", apnsErr.Reason), string(body))
+	default:
+		return ResultFailedRetriable, NewProviderError(fmt.Sprintf("APNs delivery failed (status %d): %s", resp.StatusCode, apnsErr.Reason), string(body))
+	}
+}
+
+// providerToken returns a cached JWT provider token, minting a new one every ~50 minutes as Apple requires.
+func (p *APNsProvider) providerToken() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cachedToken != "" && time.Now().Before(p.tokenExpiry) {
+		return p.cachedToken, nil
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": p.cfg.TeamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = p.cfg.KeyID
+
+	signed, err := token.SignedString(p.signingKey)
+	if err != nil {
+		return "", err
+	}
+
+	p.cachedToken = signed
+	p.tokenExpiry = now.Add(50 * time.Minute)
+	return p.cachedToken, nil
+}
@@ -0,0 +1,137 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"delivery-service/internal/models"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const fcmHost = "https://fcm.googleapis.com/v1"
+
+const fcmMessagingScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+// FCMConfig configures the Firebase Cloud Messaging provider.
+type FCMConfig struct {
+	ProjectID       string // Firebase project ID
+	CredentialsPath string // path to the service-account JSON key
+	MaxConnsPerHost int    // HTTP connection pool size
+	RateLimitPerSec int    // requests/sec budget, bounded by the project's FCM quota
+}
+
+// FCMProvider sends push notifications to Android/web devices via the FCM HTTP v1 API.
+type FCMProvider struct {
+	cfg         FCMConfig
+	client      *http.Client
+	limiter     *TokenBucket
+	tokenSource oauth2.TokenSource
+}
+
+// NewFCMProvider builds an OAuth2-authenticated, pooled and rate-limited FCM client.
+// A cfg with no CredentialsPath registers the provider without credentials; Send then fails
+// retriably until the deployment supplies a real service account.
+func NewFCMProvider(cfg FCMConfig) (*FCMProvider, error) {
+	limiter := NewTokenBucket(cfg.RateLimitPerSec)
+
+	if cfg.CredentialsPath == "" {
+		return &FCMProvider{cfg: cfg, limiter: limiter}, nil
+	}
+
+	keyBytes, err := os.ReadFile(cfg.CredentialsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read FCM service account key: %w", err)
+	}
+
+	creds, err := google.CredentialsFromJSON(context.Background(), keyBytes, fcmMessagingScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load FCM credentials: %w", err)
+	}
+
+	transport := &http.Transport{
+		MaxConnsPerHost:     cfg.MaxConnsPerHost,
+		MaxIdleConnsPerHost: cfg.MaxConnsPerHost,
+	}
+
+	return &FCMProvider{
+		cfg:         cfg,
+		client:      &http.Client{Transport: transport, Timeout: 10 * time.Second},
+		limiter:     limiter,
+		tokenSource: creds.TokenSource,
+	}, nil
+}
+
+// Name identifies this provider in logs and metrics.
+func (p *FCMProvider) Name() string { return "fcm" }
+
+// Send delivers a single push notification to FCM and classifies the response.
+func (p *FCMProvider) Send(ctx context.Context, notification models.Notification) (Result, error) {
+	if p.tokenSource == nil {
+		return ResultFailedRetriable, fmt.Errorf("FCM provider not configured")
+	}
+
+	if err := p.limiter.Wait(ctx); err != nil {
+		return ResultFailedRetriable, fmt.Errorf("FCM rate limiter: %w", err)
+	}
+
+	oauthToken, err := p.tokenSource.Token()
+	if err != nil {
+		return ResultFailedRetriable, fmt.Errorf("failed to mint FCM OAuth2 token: %w", err)
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"message": map[string]interface{}{
+			"token": notification.Recipient,
+			"notification": map[string]string{
+				"title": notification.Subject,
+				"body":  notification.Message,
+			},
+		},
+	})
+	if err != nil {
+		return ResultFailedRetriable, fmt.Errorf("failed to marshal FCM payload: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/projects/%s/messages:send", fcmHost, p.cfg.ProjectID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return ResultFailedRetriable, fmt.Errorf("failed to build FCM request: %w", err)
+	}
+
+	req.Header.Set("authorization", "Bearer "+oauthToken.AccessToken)
+	req.Header.Set("content-type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return ResultFailedRetriable, fmt.Errorf("FCM request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode == http.StatusOK {
+		return ResultSent, nil
+	}
+
+	var fcmErr struct {
+		Error struct {
+			Status string `json:"status"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(body, &fcmErr)
+
+	switch fcmErr.Error.Status {
+	case "NOT_FOUND", "UNREGISTERED", "INVALID_ARGUMENT":
+		return ResultInvalidToken, NewProviderError(fmt.Sprintf("FCM rejected device token: %s", fcmErr.Error.Status), string(body))
+	default:
+		return ResultFailedRetriable, NewProviderError(fmt.Sprintf("FCM delivery failed (status %d): %s", resp.StatusCode, fcmErr.Error.Status), string(body))
+	}
+}
@@ -0,0 +1,126 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+
+	"delivery-service/internal/models"
+	"delivery-service/internal/telemetry"
+)
+
+// Result classifies the outcome of a push delivery attempt so callers can tell a transient
+// carrier error apart from a device token that will never work again.
+type Result string
+
+const (
+	ResultSent            Result = "SENT"
+	ResultFailedRetriable Result = "FAILED_RETRIABLE"
+	ResultInvalidToken    Result = "INVALID_TOKEN"
+)
+
+// apnsTokenPattern matches the 64-hex-character device tokens APNs issues; anything else is
+// treated as an FCM registration token.
+var apnsTokenPattern = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// ProviderError wraps a push delivery failure with the raw carrier response body, so
+// classifyPushError can attach it to the structured DeliveryError as ProviderResponse without
+// every provider needing to know about models.DeliveryError.
+type ProviderError struct {
+	msg      string
+	response string
+}
+
+// NewProviderError builds a ProviderError from a classification message and the raw response body
+// the carrier returned alongside it.
+func NewProviderError(msg, response string) *ProviderError {
+	return &ProviderError{msg: msg, response: response}
+}
+
+func (e *ProviderError) Error() string { return e.msg }
+
+// PushProvider sends a single push notification to a carrier and classifies the response.
+type PushProvider interface {
+	Name() string
+	Send(ctx context.Context, notification models.Notification) (Result, error)
+}
+
+// Registry holds the configured push providers and picks the right one per notification.
+type Registry struct {
+	apns *APNsProvider
+	fcm  *FCMProvider
+}
+
+// NewRegistry builds a provider registry from the APNs/FCM config. A provider whose config has no
+// credentials stays registered but fails closed with FAILED_RETRIABLE until configured.
+func NewRegistry(apnsCfg APNsConfig, fcmCfg FCMConfig) (*Registry, error) {
+	apns, err := NewAPNsProvider(apnsCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize APNs provider: %w", err)
+	}
+
+	fcm, err := NewFCMProvider(fcmCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize FCM provider: %w", err)
+	}
+
+	return &Registry{apns: apns, fcm: fcm}, nil
+}
+
+// Select picks the provider matching the notification's device token format.
+func (r *Registry) Select(notification models.Notification) PushProvider {
+	if apnsTokenPattern.MatchString(notification.Recipient) {
+		return r.apns
+	}
+	return r.fcm
+}
+
+// Deliver sends the notification through the selected provider, reporting the outcome in the
+// (status, deliveryTimeMs, error) shape the rest of the delivery handlers expect.
+func (r *Registry) Deliver(ctx context.Context, notification models.Notification) (string, int64, error) {
+	ctx, span := telemetry.StartSpan(ctx, "providers.Deliver")
+	defer span.End()
+
+	startTime := time.Now()
+
+	provider := r.Select(notification)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	result, err := provider.Send(ctx, notification)
+	deliveryTime := time.Since(startTime).Milliseconds()
+
+	if result == ResultSent {
+		log.Printf("🔔 Push sent to %s via %s (took %dms)\n", notification.Recipient, provider.Name(), deliveryTime)
+		return "SENT", deliveryTime, nil
+	}
+
+	log.Printf("❌ Push to %s via %s classified as %s: %v\n", notification.Recipient, provider.Name(), result, err)
+	return "FAILED", deliveryTime, classifyPushError(result, err)
+}
+
+// classifyPushError turns a provider's Result classification into the structured DeliveryError the
+// rest of the pipeline expects, preserving the provider's own retriable/invalid-token distinction
+// instead of re-deriving it from the error message.
+func classifyPushError(result Result, err error) *models.DeliveryError {
+	de := &models.DeliveryError{Message: err.Error(), Retriable: result == ResultFailedRetriable}
+
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		de.ProviderResponse = providerErr.response
+	}
+
+	switch result {
+	case ResultInvalidToken:
+		de.Code = models.ErrorCodeInvalidToken
+		de.Hint = "Recipient uninstalled the app or revoked push permission; drop the token"
+	default:
+		de.Code = models.ErrorCodeUnknown
+	}
+
+	return de
+}
@@ -0,0 +1,65 @@
+package providers
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TokenBucket is a simple per-provider rate limiter that refills continuously at a fixed rate.
+type TokenBucket struct {
+	mu         sync.Mutex
+	tokens     float64
+	maxTokens  float64
+	refillRate float64 // tokens per second
+	lastRefill time.Time
+}
+
+// NewTokenBucket creates a bucket allowing up to ratePerSec requests/sec, bursting up to that size.
+// A non-positive ratePerSec disables throttling (the bucket never runs dry).
+func NewTokenBucket(ratePerSec int) *TokenBucket {
+	if ratePerSec <= 0 {
+		ratePerSec = 1 << 20
+	}
+
+	return &TokenBucket{
+		tokens:     float64(ratePerSec),
+		maxTokens:  float64(ratePerSec),
+		refillRate: float64(ratePerSec),
+		lastRefill: time.Now(),
+	}
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	for {
+		if b.takeToken() {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func (b *TokenBucket) takeToken() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(b.lastRefill).Seconds()
+	b.lastRefill = now
+
+	b.tokens += elapsed * b.refillRate
+	if b.tokens > b.maxTokens {
+		b.tokens = b.maxTokens
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true
+	}
+	return false
+}
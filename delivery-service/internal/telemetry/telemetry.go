@@ -0,0 +1,119 @@
+// Package telemetry wires OpenTelemetry tracing and Prometheus metrics for delivery-service, and
+// carries trace context across the Kafka hop via traceparent/tracestate message headers.
+package telemetry
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer is the package-wide tracer for delivery-service spans. It is usable before InitTracing
+// runs (falling back to the global no-op provider) so packages can take a dependency on it freely.
+var Tracer = otel.Tracer("delivery-service")
+
+// InitTracing installs an always-sampling TracerProvider and a W3C trace-context propagator so a
+// single trace can span the API gateway, Kafka, and this service. Exporting spans to a collector
+// is a deployment concern (wire an SDK exporter before calling this in production).
+func InitTracing(serviceName string) {
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	Tracer = tp.Tracer(serviceName)
+}
+
+// Prometheus metrics shared across handlers and the mongo client.
+var (
+	NotificationsSentTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "notifications_sent_total",
+		Help: "Total notifications processed, by type and status (one count per delivery attempt).",
+	}, []string{"type", "status"})
+
+	DeliverySeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "notification_delivery_seconds",
+		Help:    "Delivery attempt latency by notification type.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"type"})
+
+	KafkaConsumerLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_consumer_lag",
+		Help: "Approximate consumer lag, by topic and consumer group.",
+	}, []string{"topic", "group"})
+
+	MongoWriteErrorsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "mongo_write_errors_total",
+		Help: "Total MongoDB write failures.",
+	})
+)
+
+// ServeMetrics exposes /metrics on its own admin port, separate from the Kafka consumer traffic.
+func ServeMetrics(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	go func() {
+		log.Printf("📊 Metrics server listening on %s/metrics\n", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Printf("⚠️ Metrics server stopped: %v\n", err)
+		}
+	}()
+}
+
+// KafkaHeaderCarrier adapts kafka-go message headers to otel's propagation.TextMapCarrier.
+type KafkaHeaderCarrier struct {
+	Headers *[]kafka.Header
+}
+
+// Get returns the value of the first header named key, or "" if absent.
+func (c KafkaHeaderCarrier) Get(key string) string {
+	for _, h := range *c.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// Set replaces (or appends) the header named key.
+func (c KafkaHeaderCarrier) Set(key, value string) {
+	for i, h := range *c.Headers {
+		if h.Key == key {
+			(*c.Headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*c.Headers = append(*c.Headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+// Keys returns every header name currently set.
+func (c KafkaHeaderCarrier) Keys() []string {
+	keys := make([]string, len(*c.Headers))
+	for i, h := range *c.Headers {
+		keys[i] = h.Key
+	}
+	return keys
+}
+
+// InjectKafkaHeaders writes the span context from ctx into headers as traceparent/tracestate.
+func InjectKafkaHeaders(ctx context.Context, headers *[]kafka.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, KafkaHeaderCarrier{Headers: headers})
+}
+
+// ExtractKafkaHeaders reads traceparent/tracestate from headers into a new context derived from ctx.
+func ExtractKafkaHeaders(ctx context.Context, headers []kafka.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, KafkaHeaderCarrier{Headers: &headers})
+}
+
+// StartSpan is a small convenience wrapper so call sites don't each import go.opentelemetry.io/otel/trace.
+func StartSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return Tracer.Start(ctx, name)
+}